@@ -0,0 +1,195 @@
+package azurerm
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/arm/disk"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceArmManagedDisk() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArmManagedDiskCreate,
+		Read:   resourceArmManagedDiskRead,
+		Update: resourceArmManagedDiskCreate,
+		Delete: resourceArmManagedDiskDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"location": &schema.Schema{
+				Type:      schema.TypeString,
+				Required:  true,
+				ForceNew:  true,
+				StateFunc: azureRMNormalizeLocation,
+			},
+
+			"resource_group_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"storage_account_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(disk.PremiumLRS),
+					string(disk.StandardLRS),
+				}, false),
+			},
+
+			"create_option": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(disk.Empty),
+					string(disk.Import),
+					string(disk.Copy),
+				}, false),
+			},
+
+			"source_uri": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"source_resource_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"os_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"disk_size_gb": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+
+			"tags": tagsSchema(),
+		},
+	}
+}
+
+func resourceArmManagedDiskCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ArmClient)
+	diskClient := client.diskClient
+
+	name := d.Get("name").(string)
+	location := d.Get("location").(string)
+	resGroup := d.Get("resource_group_name").(string)
+	createOption := d.Get("create_option").(string)
+	storageAccountType := d.Get("storage_account_type").(string)
+	osType := d.Get("os_type").(string)
+	diskSize := int32(d.Get("disk_size_gb").(int))
+	tags := d.Get("tags").(map[string]interface{})
+	expandedTags := expandTags(tags)
+
+	createDisk := disk.Model{
+		Name:     &name,
+		Location: &location,
+		Tags:     expandedTags,
+		Properties: &disk.Properties{
+			CreationData: &disk.CreationData{
+				CreateOption: disk.DiskCreateOption(createOption),
+			},
+			AccountType: disk.StorageAccountTypes(storageAccountType),
+			DiskSizeGB:  &diskSize,
+		},
+	}
+
+	if osType != "" {
+		createDisk.Properties.OsType = disk.OperatingSystemTypes(osType)
+	}
+
+	switch strmode := disk.DiskCreateOption(createOption); strmode {
+	case disk.Import:
+		sourceUri := d.Get("source_uri").(string)
+		createDisk.Properties.CreationData.SourceURI = &sourceUri
+	case disk.Copy:
+		sourceResourceId := d.Get("source_resource_id").(string)
+		createDisk.Properties.CreationData.SourceResourceID = &sourceResourceId
+	}
+
+	_, err := diskClient.CreateOrUpdate(resGroup, name, createDisk, make(chan struct{}))
+	if err != nil {
+		return err
+	}
+
+	read, err := diskClient.Get(resGroup, name)
+	if err != nil {
+		return err
+	}
+	if read.ID == nil {
+		return fmt.Errorf("Cannot read Managed Disk %s (resource group %s) ID", name, resGroup)
+	}
+
+	d.SetId(*read.ID)
+
+	return resourceArmManagedDiskRead(d, meta)
+}
+
+func resourceArmManagedDiskRead(d *schema.ResourceData, meta interface{}) error {
+	diskClient := meta.(*ArmClient).diskClient
+
+	id, err := parseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+	resGroup := id.ResourceGroup
+	name := id.Path["disks"]
+
+	resp, err := diskClient.Get(resGroup, name)
+	if resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Error making Read request on Azure Managed Disk %s: %s", name, err)
+	}
+
+	d.Set("name", resp.Name)
+	d.Set("resource_group_name", resGroup)
+	d.Set("location", azureRMNormalizeLocation(*resp.Location))
+
+	if resp.Properties != nil {
+		d.Set("storage_account_type", string(resp.Properties.AccountType))
+		d.Set("disk_size_gb", resp.Properties.DiskSizeGB)
+		d.Set("os_type", string(resp.Properties.OsType))
+
+		if resp.Properties.CreationData != nil {
+			d.Set("create_option", string(resp.Properties.CreationData.CreateOption))
+		}
+	}
+
+	flattenAndSetTags(d, resp.Tags)
+
+	return nil
+}
+
+func resourceArmManagedDiskDelete(d *schema.ResourceData, meta interface{}) error {
+	diskClient := meta.(*ArmClient).diskClient
+
+	id, err := parseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+	resGroup := id.ResourceGroup
+	name := id.Path["disks"]
+
+	_, err = diskClient.Delete(resGroup, name, make(chan struct{}))
+
+	return err
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/arm/compute"
 	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
@@ -116,7 +118,19 @@ func resourceArmVirtualMachine() *schema.Resource {
 
 						"vhd_uri": &schema.Schema{
 							Type:     schema.TypeString,
-							Required: true,
+							Optional: true,
+						},
+
+						"managed_disk_type": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+
+						"managed_disk_id": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
 						},
 
 						"caching": &schema.Schema{
@@ -147,7 +161,19 @@ func resourceArmVirtualMachine() *schema.Resource {
 
 						"vhd_uri": &schema.Schema{
 							Type:     schema.TypeString,
-							Required: true,
+							Optional: true,
+						},
+
+						"managed_disk_type": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+
+						"managed_disk_id": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
 						},
 
 						"create_option": &schema.Schema{
@@ -174,10 +200,10 @@ func resourceArmVirtualMachine() *schema.Resource {
 				Required: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
-						"compute_name": &schema.Schema{
+						"computer_name": &schema.Schema{
 							Type:     schema.TypeString,
-							Optional: true,
-							Computed: true,
+							Required: true,
+							ForceNew: true,
 						},
 
 						"admin_username": &schema.Schema{
@@ -195,14 +221,106 @@ func resourceArmVirtualMachine() *schema.Resource {
 							Optional: true,
 							Computed: true,
 						},
+					},
+				},
+				Set: resourceArmVirtualMachineStorageOsProfileHash,
+			},
 
-						"lun": &schema.Schema{
-							Type:     schema.TypeInt,
+			"os_profile_linux_config": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"disable_password_authentication": &schema.Schema{
+							Type:     schema.TypeBool,
 							Required: true,
 						},
+
+						"ssh_keys": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"path": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+
+									"key_data": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+								},
+							},
+						},
 					},
 				},
-				Set: resourceArmVirtualMachineStorageDataDiskHash,
+				Set: resourceArmVirtualMachineStorageOsProfileLinuxConfigHash,
+			},
+
+			"os_profile_windows_config": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"provision_vm_agent": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+
+						"enable_automatic_upgrades": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+
+						"winrm": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"protocol": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+
+									"certificate_url": &schema.Schema{
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+
+						"additional_unattend_config": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"pass": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+
+									"component": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+
+									"setting_name": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+
+									"content": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+								},
+							},
+						},
+					},
+				},
+				Set: resourceArmVirtualMachineStorageOsProfileWindowsConfigHash,
 			},
 
 			"network_interface_ids": &schema.Schema{
@@ -211,6 +329,36 @@ func resourceArmVirtualMachine() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 				Set:      schema.HashString,
 			},
+
+			"diagnostics_profile": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"boot_diagnostics": &schema.Schema{
+							Type:     schema.TypeSet,
+							Required: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"enabled": &schema.Schema{
+										Type:     schema.TypeBool,
+										Required: true,
+									},
+
+									"storage_uri": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+
+			"tags": tagsSchema(),
 		},
 	}
 }
@@ -225,7 +373,10 @@ func resourceArmVirtualMachineCreate(d *schema.ResourceData, meta interface{}) e
 	location := d.Get("location").(string)
 	resGroup := d.Get("resource_group_name").(string)
 	network_profile := expandAzureRmVirtualMachineNetworkProfile(d)
-	os_disk := expandAzureRmVirtualMachineOsDisk(d)
+	os_disk, err := expandAzureRmVirtualMachineOsDisk(d)
+	if err != nil {
+		return err
+	}
 	vm_size := d.Get("vm_size").(string)
 
 	storage_profile := compute.StorageProfile{
@@ -248,12 +399,18 @@ func resourceArmVirtualMachineCreate(d *schema.ResourceData, meta interface{}) e
 		storage_profile.DataDisks = &data_disks
 	}
 
+	os_profile, err := expandAzureRmVirtualMachineOsProfile(d)
+	if err != nil {
+		return err
+	}
+
 	properties := compute.VirtualMachineProperties{
 		NetworkProfile: &network_profile,
 		HardwareProfile: &compute.HardwareProfile{
 			VMSize: compute.VirtualMachineSizeTypes(vm_size),
 		},
 		StorageProfile: &storage_profile,
+		OsProfile:      &os_profile,
 	}
 
 	if v, ok := d.GetOk("availability_set_id"); ok {
@@ -281,11 +438,31 @@ func resourceArmVirtualMachineCreate(d *schema.ResourceData, meta interface{}) e
 		vm.Plan = &plan
 	}
 
-	_, err := vmClient.CreateOrUpdate(resGroup, name, vm)
+	if _, ok := d.GetOk("diagnostics_profile"); ok {
+		diagnosticsProfile := expandAzureRmVirtualMachineDiagnosticsProfile(d)
+		properties.DiagnosticsProfile = &diagnosticsProfile
+	}
+
+	if v, ok := d.GetOk("tags"); ok {
+		tags := v.(map[string]interface{})
+		vm.Tags = expandTags(tags)
+	}
+
+	resp, err := vmClient.CreateOrUpdate(resGroup, name, vm)
 	if err != nil {
 		return err
 	}
 
+	if resp.ID == nil {
+		return fmt.Errorf("Cannot read Azure Virtual Machine %s (resource group %s) ID", name, resGroup)
+	}
+
+	d.SetId(*resp.ID)
+
+	if err := resourceArmVirtualMachineWaitForState(vmClient, resGroup, name); err != nil {
+		return err
+	}
+
 	return resourceArmVirtualMachineRead(d, meta)
 }
 
@@ -307,10 +484,156 @@ func resourceArmVirtualMachineRead(d *schema.ResourceData, meta interface{}) err
 	if err != nil {
 		return fmt.Errorf("Error making Read request on Azure Virtual Machine %s: %s", name, err)
 	}
+
+	d.Set("name", resp.Name)
+	d.Set("location", azureRMNormalizeLocation(*resp.Location))
+	d.Set("resource_group_name", resGroup)
+
+	if resp.Properties == nil {
+		return nil
+	}
+
+	if resp.Properties.AvailabilitySet != nil {
+		d.Set("availability_set_id", resp.Properties.AvailabilitySet.ID)
+	}
+
+	if resp.Properties.HardwareProfile != nil {
+		d.Set("vm_size", string(resp.Properties.HardwareProfile.VMSize))
+	}
+
+	if resp.Properties.NetworkProfile != nil {
+		if err := d.Set("network_interface_ids", flattenAzureRmVirtualMachineNetworkInterfaces(resp.Properties.NetworkProfile)); err != nil {
+			return fmt.Errorf("[ERROR] Error flattening network_interface_ids: %s", err)
+		}
+	}
+
+	if resp.Properties.StorageProfile != nil {
+		if resp.Properties.StorageProfile.ImageReference != nil {
+			if err := d.Set("storage_image_reference", schema.NewSet(resourceArmVirtualMachineStorageImageReferenceHash, flattenAzureRmVirtualMachineImageReference(resp.Properties.StorageProfile.ImageReference))); err != nil {
+				return fmt.Errorf("[ERROR] Error flattening storage_image_reference: %s", err)
+			}
+		}
+
+		if resp.Properties.StorageProfile.OsDisk != nil {
+			if err := d.Set("storage_os_disk", schema.NewSet(resourceArmVirtualMachineStorageOsDiskHash, flattenAzureRmVirtualMachineOsDisk(resp.Properties.StorageProfile.OsDisk))); err != nil {
+				return fmt.Errorf("[ERROR] Error flattening storage_os_disk: %s", err)
+			}
+		}
+
+		if resp.Properties.StorageProfile.DataDisks != nil {
+			if err := d.Set("storage_data_disk", schema.NewSet(resourceArmVirtualMachineStorageDataDiskHash, flattenAzureRmVirtualMachineDataDisk(resp.Properties.StorageProfile.DataDisks))); err != nil {
+				return fmt.Errorf("[ERROR] Error flattening storage_data_disk: %s", err)
+			}
+		}
+	}
+
+	if resp.Properties.OsProfile != nil {
+		if err := d.Set("os_profile", schema.NewSet(resourceArmVirtualMachineStorageOsProfileHash, flattenAzureRmVirtualMachineOsProfile(resp.Properties.OsProfile))); err != nil {
+			return fmt.Errorf("[ERROR] Error flattening os_profile: %s", err)
+		}
+	}
+
+	if resp.Properties.DiagnosticsProfile != nil && resp.Properties.DiagnosticsProfile.BootDiagnostics != nil {
+		if err := d.Set("diagnostics_profile", schema.NewSet(resourceArmVirtualMachineDiagnosticsProfileHash, flattenAzureRmVirtualMachineDiagnosticsProfile(resp.Properties.DiagnosticsProfile))); err != nil {
+			return fmt.Errorf("[ERROR] Error flattening diagnostics_profile: %s", err)
+		}
+	}
+
+	if resp.Plan != nil {
+		if err := d.Set("plan", schema.NewSet(resourceArmVirtualMachinePlanHash, flattenAzureRmVirtualMachinePlan(resp.Plan))); err != nil {
+			return fmt.Errorf("[ERROR] Error flattening plan: %s", err)
+		}
+	}
+
+	flattenAndSetTags(d, resp.Tags)
+
 	return nil
 }
 
+// resourceArmVirtualMachineWaitForState polls until the VM's ProvisioningState
+// reaches Succeeded or Failed. The vendored helper/resource.StateChangeConf in
+// this tree predates WaitForStateContext, so there's no way to plumb request
+// cancellation through the poll loop; Timeout is the only bound on how long it runs.
+func resourceArmVirtualMachineWaitForState(vmClient compute.VirtualMachinesClient, resGroup string, name string) error {
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"Accepted", "Updating", "Creating"},
+		Target:     []string{"Succeeded"},
+		Refresh:    resourceArmVirtualMachineStateRefreshFunc(vmClient, resGroup, name),
+		Timeout:    30 * time.Minute,
+		MinTimeout: 15 * time.Second,
+	}
+
+	_, err := stateConf.WaitForState()
+	return err
+}
+
+func resourceArmVirtualMachineStateRefreshFunc(vmClient compute.VirtualMachinesClient, resGroup string, name string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		resp, err := vmClient.Get(resGroup, name, "instanceView")
+		if err != nil {
+			return nil, "", fmt.Errorf("Error issuing read request of Azure Virtual Machine %s: %s", name, err)
+		}
+
+		if resp.Properties == nil || resp.Properties.ProvisioningState == nil {
+			return resp, "Creating", nil
+		}
+
+		state := *resp.Properties.ProvisioningState
+		if state == "Failed" {
+			return resp, state, fmt.Errorf("Azure Virtual Machine %s entered a Failed provisioning state", name)
+		}
+
+		return resp, state, nil
+	}
+}
+
 func resourceArmVirtualMachineUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ArmClient)
+	vmClient := client.vmClient
+
+	if !d.HasChange("storage_data_disk") && !d.HasChange("tags") {
+		return resourceArmVirtualMachineRead(d, meta)
+	}
+
+	resGroup := d.Get("resource_group_name").(string)
+	name := d.Get("name").(string)
+
+	resp, err := vmClient.Get(resGroup, name, "")
+	if err != nil {
+		return fmt.Errorf("Error reading Azure Virtual Machine %s for update: %s", name, err)
+	}
+
+	if d.HasChange("storage_data_disk") {
+		data_disks, err := expandAzureRmVirtualMachineDataDisk(d)
+		if err != nil {
+			return err
+		}
+
+		seenLuns := make(map[int]bool)
+		for _, disk := range data_disks {
+			if seenLuns[*disk.Lun] {
+				return fmt.Errorf("[ERROR] LUN %d is assigned to more than one storage_data_disk", *disk.Lun)
+			}
+			seenLuns[*disk.Lun] = true
+		}
+
+		resp.Properties.StorageProfile.DataDisks = &data_disks
+	}
+
+	if d.HasChange("tags") {
+		tags := d.Get("tags").(map[string]interface{})
+		resp.Tags = expandTags(tags)
+	}
+
+	_, err = vmClient.CreateOrUpdate(resGroup, name, resp)
+	if err != nil {
+		return fmt.Errorf("Error updating Azure Virtual Machine %s: %s", name, err)
+	}
+
+	if err := resourceArmVirtualMachineWaitForState(vmClient, resGroup, name); err != nil {
+		return err
+	}
+
 	return resourceArmVirtualMachineRead(d, meta)
 }
 
@@ -324,9 +647,41 @@ func resourceArmVirtualMachineDelete(d *schema.ResourceData, meta interface{}) e
 	resGroup := id.ResourceGroup
 	name := id.Path["virtualMachines"]
 
-	_, err = vmClient.Delete(resGroup, name)
+	resp, err := vmClient.Delete(resGroup, name)
+	if err != nil {
+		if resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("Error deleting Azure Virtual Machine %s: %s", name, err)
+	}
 
-	return err
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"Deleting"},
+		Target:     []string{"Deleted"},
+		Refresh:    resourceArmVirtualMachineDeleteRefreshFunc(vmClient, resGroup, name),
+		Timeout:    30 * time.Minute,
+		MinTimeout: 15 * time.Second,
+	}
+
+	if _, err := stateConf.WaitForState(); err != nil {
+		return fmt.Errorf("Error waiting for Azure Virtual Machine %s to be deleted: %s", name, err)
+	}
+
+	return nil
+}
+
+func resourceArmVirtualMachineDeleteRefreshFunc(vmClient compute.VirtualMachinesClient, resGroup string, name string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		resp, err := vmClient.Get(resGroup, name, "")
+		if resp.StatusCode == http.StatusNotFound {
+			return resp, "Deleted", nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("Error polling for deletion of Azure Virtual Machine %s: %s", name, err)
+		}
+
+		return resp, "Deleting", nil
+	}
 }
 
 func resourceArmVirtualMachinePlanHash(v interface{}) int {
@@ -372,51 +727,95 @@ func resourceArmVirtualMachineStorageOsDiskHash(v interface{}) int {
 	return hashcode.String(buf.String())
 }
 
+func resourceArmVirtualMachineStorageOsProfileHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	buf.WriteString(fmt.Sprintf("%s-", m["computer_name"].(string)))
+	buf.WriteString(fmt.Sprintf("%s-", m["admin_username"].(string)))
+
+	return hashcode.String(buf.String())
+}
+
+func resourceArmVirtualMachineStorageOsProfileLinuxConfigHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	buf.WriteString(fmt.Sprintf("%t-", m["disable_password_authentication"].(bool)))
+
+	return hashcode.String(buf.String())
+}
+
+func resourceArmVirtualMachineStorageOsProfileWindowsConfigHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	if v, ok := m["provision_vm_agent"]; ok {
+		buf.WriteString(fmt.Sprintf("%t-", v.(bool)))
+	}
+	if v, ok := m["enable_automatic_upgrades"]; ok {
+		buf.WriteString(fmt.Sprintf("%t-", v.(bool)))
+	}
+
+	return hashcode.String(buf.String())
+}
+
 func expandAzureRmVirtualMachinePlan(d *schema.ResourceData) (compute.Plan, error) {
-	planconfig := d.Get("plan").([]interface{})
+	planConfig := d.Get("plan").(*schema.Set).List()
 
-	if len(planconfig) == 1 {
-		publisher := planconfig[0]["publisher"].(string)
-		name := planconfig[0]["name"].(string)
-		product := planconfig[0]["product"].(string)
+	if len(planConfig) != 1 {
+		return compute.Plan{}, fmt.Errorf("Cannot specify more than one plan.")
+	}
 
-		plan := compute.Plan{
-			Publisher: &publisher,
-			Name:      &name,
-			Product:   &product,
-		}
+	config := planConfig[0].(map[string]interface{})
 
-		return plan, nil
+	publisher := config["publisher"].(string)
+	name := config["name"].(string)
+	product := config["product"].(string)
 
-	} else {
-		return nil, fmt.Errorf("Cannot specify more than one plan.")
+	plan := compute.Plan{
+		Publisher: &publisher,
+		Name:      &name,
+		Product:   &product,
 	}
 
-	return nil, nil
+	return plan, nil
 }
 
 func expandAzureRmVirtualMachineDataDisk(d *schema.ResourceData) ([]compute.DataDisk, error) {
-	disks := d.Get("storage_data_disk").([]interface{})
+	disks := d.Get("storage_data_disk").(*schema.Set).List()
 	data_disks := make([]compute.DataDisk, 0, len(disks))
 	for _, disk_config := range disks {
 		config := disk_config.(map[string]interface{})
 
 		name := config["name"].(string)
-		vhd := config["vhd_uri"].(string)
 		createOption := config["create_option"].(string)
 		lun := config["lun"].(int)
 		disk_size := config["disk_size_gb"].(int)
 
 		data_disk := compute.DataDisk{
-			Name: &name,
-			Vhd: &compute.VirtualHardDisk{
-				URI: &vhd,
-			},
+			Name:         &name,
 			Lun:          &lun,
 			DiskSizeGB:   &disk_size,
 			CreateOption: compute.DiskCreateOptionTypes(createOption),
 		}
 
+		managedDiskType := config["managed_disk_type"].(string)
+		managedDiskID := config["managed_disk_id"].(string)
+
+		if managedDiskType != "" || managedDiskID != "" {
+			managedDisk := &compute.ManagedDiskParameters{}
+			if managedDiskType != "" {
+				managedDisk.StorageAccountType = compute.StorageAccountTypes(managedDiskType)
+			}
+			if managedDiskID != "" {
+				managedDisk.ID = &managedDiskID
+			}
+			data_disk.ManagedDisk = managedDisk
+		} else {
+			vhd := config["vhd_uri"].(string)
+			data_disk.Vhd = &compute.VirtualHardDisk{
+				URI: &vhd,
+			}
+		}
+
 		data_disks = append(data_disks, data_disk)
 	}
 
@@ -424,28 +823,31 @@ func expandAzureRmVirtualMachineDataDisk(d *schema.ResourceData) ([]compute.Data
 }
 
 func expandAzureRmVirtualMachineImageReference(d *schema.ResourceData) (compute.ImageReference, error) {
-	ws := d.Get("storage_image_reference").([]interface{})
+	return expandAzureRmVirtualMachineImageReferenceFromField(d, "storage_image_reference")
+}
 
-	if len(ws) == 1 {
-		publisher := ws[0]["publisher"].(string)
-		offer := ws[0]["offer"].(string)
-		sku := ws[0]["sku"].(string)
-		version := ws[0]["version"].(string)
+func expandAzureRmVirtualMachineImageReferenceFromField(d *schema.ResourceData, fieldName string) (compute.ImageReference, error) {
+	imageConfig := d.Get(fieldName).(*schema.Set).List()
 
-		image_reference := compute.ImageReference{
-			Publisher: &publisher,
-			Offer:     &offer,
-			Sku:       &sku,
-			Version:   &version,
-		}
+	if len(imageConfig) != 1 {
+		return compute.ImageReference{}, fmt.Errorf("Cannot specify more than one %s.", fieldName)
+	}
 
-		return image_reference, nil
+	config := imageConfig[0].(map[string]interface{})
 
-	} else {
-		return nil, fmt.Errorf("Cannot specify more than one storage_image_reference.")
+	publisher := config["publisher"].(string)
+	offer := config["offer"].(string)
+	sku := config["sku"].(string)
+	version := config["version"].(string)
+
+	imageReference := compute.ImageReference{
+		Publisher: &publisher,
+		Offer:     &offer,
+		Sku:       &sku,
+		Version:   &version,
 	}
 
-	return nil, nil
+	return imageReference, nil
 }
 
 func expandAzureRmVirtualMachineNetworkProfile(d *schema.ResourceData) compute.NetworkProfile {
@@ -467,28 +869,329 @@ func expandAzureRmVirtualMachineNetworkProfile(d *schema.ResourceData) compute.N
 	return network_profile
 }
 
-func expandAzureRmVirtualMachineOsDisk(d *schema.ResourceData) compute.OSDisk {
+func expandAzureRmVirtualMachineOsDisk(d *schema.ResourceData) (compute.OSDisk, error) {
 	disks := d.Get("storage_os_disk").(*schema.Set).List()
-	if len(disks) > 1 {
-		return fmt.Errorf("[ERROR] Only 1 OS Disk Can be specified for an Azure RM Virtual Machine")
+	if len(disks) != 1 {
+		return compute.OSDisk{}, fmt.Errorf("[ERROR] Only 1 OS Disk Can be specified for an Azure RM Virtual Machine")
+	}
+
+	disk := disks[0].(map[string]interface{})
+
+	name := disk["name"].(string)
+	createOption := disk["create_option"].(string)
+	osDisk := compute.OSDisk{
+		Name:         &name,
+		CreateOption: compute.DiskCreateOptionTypes(createOption),
+	}
+
+	managedDiskType := disk["managed_disk_type"].(string)
+	managedDiskID := disk["managed_disk_id"].(string)
+
+	if managedDiskType != "" || managedDiskID != "" {
+		managedDisk := &compute.ManagedDiskParameters{}
+		if managedDiskType != "" {
+			managedDisk.StorageAccountType = compute.StorageAccountTypes(managedDiskType)
+		}
+		if managedDiskID != "" {
+			managedDisk.ID = &managedDiskID
+		}
+		osDisk.ManagedDisk = managedDisk
+	} else {
+		vhdURI := disk["vhd_uri"].(string)
+		osDisk.Vhd = &compute.VirtualHardDisk{
+			URI: &vhdURI,
+		}
+	}
+
+	if v := disk["caching"].(string); v != "" {
+		osDisk.Caching = compute.CachingTypes(v)
+	}
+
+	return osDisk, nil
+}
+
+func expandAzureRmVirtualMachineOsProfile(d *schema.ResourceData) (compute.OSProfile, error) {
+	osProfiles := d.Get("os_profile").(*schema.Set).List()
+
+	if len(osProfiles) != 1 {
+		return compute.OSProfile{}, fmt.Errorf("Cannot specify more than one os_profile.")
+	}
+
+	osProfile := osProfiles[0].(map[string]interface{})
+
+	computerName := osProfile["computer_name"].(string)
+	adminUsername := osProfile["admin_username"].(string)
+	adminPassword := osProfile["admin_password"].(string)
+
+	profile := compute.OSProfile{
+		ComputerName:  &computerName,
+		AdminUsername: &adminUsername,
+	}
+
+	if adminPassword != "" {
+		profile.AdminPassword = &adminPassword
+	}
+
+	if v := osProfile["custom_data"].(string); v != "" {
+		profile.CustomData = &v
+	}
+
+	_, hasLinuxConfig := d.GetOk("os_profile_linux_config")
+	_, hasWindowsConfig := d.GetOk("os_profile_windows_config")
+
+	if hasLinuxConfig && hasWindowsConfig {
+		return profile, fmt.Errorf("Cannot specify both os_profile_linux_config and os_profile_windows_config for a single Virtual Machine")
+	}
+
+	if hasLinuxConfig {
+		linuxConfig, err := expandAzureRmVirtualMachineOsProfileLinuxConfig(d)
+		if err != nil {
+			return profile, err
+		}
+		profile.LinuxConfiguration = linuxConfig
+	}
+
+	if hasWindowsConfig {
+		windowsConfig, err := expandAzureRmVirtualMachineOsProfileWindowsConfig(d)
+		if err != nil {
+			return profile, err
+		}
+		profile.WindowsConfiguration = windowsConfig
+	}
+
+	if !hasLinuxConfig && !hasWindowsConfig {
+		return profile, fmt.Errorf("Either os_profile_linux_config or os_profile_windows_config must be specified")
 	}
 
-	compute.DataDisk{}
+	return profile, nil
+}
+
+func expandAzureRmVirtualMachineOsProfileLinuxConfig(d *schema.ResourceData) (*compute.LinuxConfiguration, error) {
+	osProfilesLinuxConfig := d.Get("os_profile_linux_config").(*schema.Set).List()
+
+	linuxConfig := osProfilesLinuxConfig[0].(map[string]interface{})
+	disablePasswordAuth := linuxConfig["disable_password_authentication"].(bool)
+
+	config := &compute.LinuxConfiguration{
+		DisablePasswordAuthentication: &disablePasswordAuth,
+	}
+
+	linuxKeys := linuxConfig["ssh_keys"].([]interface{})
+	sshPublicKeys := make([]compute.SSHPublicKey, 0, len(linuxKeys))
+	for _, key := range linuxKeys {
+		sshKey := key.(map[string]interface{})
+		path := sshKey["path"].(string)
+		keyData := sshKey["key_data"].(string)
+
+		sshPublicKeys = append(sshPublicKeys, compute.SSHPublicKey{
+			Path:    &path,
+			KeyData: &keyData,
+		})
+	}
+
+	if len(sshPublicKeys) > 0 {
+		config.SSH = &compute.SSHConfiguration{
+			PublicKeys: &sshPublicKeys,
+		}
+	}
+
+	return config, nil
+}
+
+func expandAzureRmVirtualMachineOsProfileWindowsConfig(d *schema.ResourceData) (*compute.WindowsConfiguration, error) {
+	osProfilesWindowsConfig := d.Get("os_profile_windows_config").(*schema.Set).List()
+
+	windowsConfig := osProfilesWindowsConfig[0].(map[string]interface{})
+	config := &compute.WindowsConfiguration{}
+
+	if v, ok := windowsConfig["provision_vm_agent"]; ok {
+		provision := v.(bool)
+		config.ProvisionVMAgent = &provision
+	}
+
+	if v, ok := windowsConfig["enable_automatic_upgrades"]; ok {
+		enableAutomaticUpgrades := v.(bool)
+		config.EnableAutomaticUpdates = &enableAutomaticUpgrades
+	}
+
+	if winRM := windowsConfig["winrm"].(*schema.Set).List(); len(winRM) > 0 {
+		winRmListeners := make([]compute.WinRMListener, 0, len(winRM))
+		for _, winRMConfig := range winRM {
+			winRMMap := winRMConfig.(map[string]interface{})
+			protocol := winRMMap["protocol"].(string)
+			listener := compute.WinRMListener{
+				Protocol: compute.ProtocolTypes(protocol),
+			}
+
+			if v := winRMMap["certificate_url"].(string); v != "" {
+				listener.CertificateURL = &v
+			}
+
+			winRmListeners = append(winRmListeners, listener)
+		}
+
+		config.WinRM = &compute.WinRMConfiguration{
+			Listeners: &winRmListeners,
+		}
+	}
+
+	if unattendConfigs := windowsConfig["additional_unattend_config"].(*schema.Set).List(); len(unattendConfigs) > 0 {
+		additionalConfigContent := make([]compute.AdditionalUnattendContent, 0, len(unattendConfigs))
+		for _, addConfig := range unattendConfigs {
+			addConfigMap := addConfig.(map[string]interface{})
+			pass := addConfigMap["pass"].(string)
+			component := addConfigMap["component"].(string)
+			settingName := addConfigMap["setting_name"].(string)
+			content := addConfigMap["content"].(string)
+
+			additionalConfigContent = append(additionalConfigContent, compute.AdditionalUnattendContent{
+				PassName:      compute.PassNames(pass),
+				ComponentName: compute.ComponentNames(component),
+				SettingName:   compute.SettingNames(settingName),
+				Content:       &content,
+			})
+		}
+
+		config.AdditionalUnattendContent = &additionalConfigContent
+	}
+
+	return config, nil
+}
+
+func resourceArmVirtualMachineDiagnosticsProfileHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	bootDiagnostics := m["boot_diagnostics"].(*schema.Set).List()
+	if len(bootDiagnostics) == 1 {
+		bd := bootDiagnostics[0].(map[string]interface{})
+		buf.WriteString(fmt.Sprintf("%t-", bd["enabled"].(bool)))
+		buf.WriteString(fmt.Sprintf("%s-", bd["storage_uri"].(string)))
+	}
+
+	return hashcode.String(buf.String())
+}
+
+func expandAzureRmVirtualMachineDiagnosticsProfile(d *schema.ResourceData) compute.DiagnosticsProfile {
+	diagnosticsProfileConfig := d.Get("diagnostics_profile").(*schema.Set).List()
+	config := diagnosticsProfileConfig[0].(map[string]interface{})
+
+	bootDiagnosticsConfig := config["boot_diagnostics"].(*schema.Set).List()
+	bootDiagnostics := bootDiagnosticsConfig[0].(map[string]interface{})
+
+	enabled := bootDiagnostics["enabled"].(bool)
+	storageURI := bootDiagnostics["storage_uri"].(string)
 
-	name := disks[0]["name"].(string)
-	vhd_uri := disks[0]["vhd_url"].(string)
-	create_option := disks[0]["create_option"].(string)
-	os_disk := compute.OSDisk{
-		Name: &name,
-		Vhd: &compute.VirtualHardDisk{
-			URI: &vhd_uri,
+	return compute.DiagnosticsProfile{
+		BootDiagnostics: &compute.BootDiagnostics{
+			Enabled:    &enabled,
+			StorageURI: &storageURI,
 		},
-		CreateOption: compute.DiskCreateOptionTypes(create_option),
+	}
+}
+
+func flattenAzureRmVirtualMachinePlan(plan *compute.Plan) []interface{} {
+	result := map[string]interface{}{
+		"name":      *plan.Name,
+		"publisher": *plan.Publisher,
+		"product":   *plan.Product,
+	}
+
+	return []interface{}{result}
+}
+
+func flattenAzureRmVirtualMachineNetworkInterfaces(profile *compute.NetworkProfile) []interface{} {
+	result := make([]interface{}, 0)
+	if profile.NetworkInterfaces != nil {
+		for _, nic := range *profile.NetworkInterfaces {
+			result = append(result, *nic.ID)
+		}
+	}
+
+	return result
+}
+
+func flattenAzureRmVirtualMachineImageReference(image *compute.ImageReference) []interface{} {
+	result := map[string]interface{}{
+		"publisher": *image.Publisher,
+		"offer":     *image.Offer,
+		"sku":       *image.Sku,
+		"version":   *image.Version,
+	}
+
+	return []interface{}{result}
+}
+
+func flattenAzureRmVirtualMachineOsDisk(disk *compute.OSDisk) []interface{} {
+	result := map[string]interface{}{
+		"name":          *disk.Name,
+		"create_option": string(disk.CreateOption),
+		"caching":       string(disk.Caching),
+	}
+
+	if disk.Vhd != nil {
+		result["vhd_uri"] = *disk.Vhd.URI
+	}
+
+	if disk.ManagedDisk != nil {
+		result["managed_disk_type"] = string(disk.ManagedDisk.StorageAccountType)
+		if disk.ManagedDisk.ID != nil {
+			result["managed_disk_id"] = *disk.ManagedDisk.ID
+		}
+	}
+
+	return []interface{}{result}
+}
+
+func flattenAzureRmVirtualMachineDataDisk(disks *[]compute.DataDisk) []interface{} {
+	result := make([]interface{}, 0, len(*disks))
+	for _, disk := range *disks {
+		data := map[string]interface{}{
+			"name":          *disk.Name,
+			"create_option": string(disk.CreateOption),
+			"disk_size_gb":  *disk.DiskSizeGB,
+			"lun":           *disk.Lun,
+		}
+
+		if disk.Vhd != nil {
+			data["vhd_uri"] = *disk.Vhd.URI
+		}
+
+		if disk.ManagedDisk != nil {
+			data["managed_disk_type"] = string(disk.ManagedDisk.StorageAccountType)
+			if disk.ManagedDisk.ID != nil {
+				data["managed_disk_id"] = *disk.ManagedDisk.ID
+			}
+		}
+
+		result = append(result, data)
+	}
+
+	return result
+}
+
+func flattenAzureRmVirtualMachineOsProfile(profile *compute.OSProfile) []interface{} {
+	result := map[string]interface{}{
+		"computer_name":  *profile.ComputerName,
+		"admin_username": *profile.AdminUsername,
+	}
+
+	if profile.CustomData != nil {
+		result["custom_data"] = *profile.CustomData
+	}
+
+	return []interface{}{result}
+}
+
+func flattenAzureRmVirtualMachineDiagnosticsProfile(profile *compute.DiagnosticsProfile) []interface{} {
+	bd := profile.BootDiagnostics
+	bootDiagnostics := map[string]interface{}{
+		"enabled":     *bd.Enabled,
+		"storage_uri": *bd.StorageURI,
 	}
 
-	if v := disks[0]["cachine"].(string); v != "" {
-		os_disk.Caching = compute.CachingTypes(v)
+	result := map[string]interface{}{
+		"boot_diagnostics": []interface{}{bootDiagnostics},
 	}
 
-	return os_disk
+	return []interface{}{result}
 }
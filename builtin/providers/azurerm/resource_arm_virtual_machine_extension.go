@@ -0,0 +1,239 @@
+package azurerm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceArmVirtualMachineExtensions() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArmVirtualMachineExtensionsCreate,
+		Read:   resourceArmVirtualMachineExtensionsRead,
+		Update: resourceArmVirtualMachineExtensionsCreate,
+		Delete: resourceArmVirtualMachineExtensionsDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"location": &schema.Schema{
+				Type:      schema.TypeString,
+				Required:  true,
+				ForceNew:  true,
+				StateFunc: azureRMNormalizeLocation,
+			},
+
+			"resource_group_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"virtual_machine_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"publisher": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"type": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"type_handler_version": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"auto_upgrade_minor_version": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			"settings": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateJsonString,
+			},
+
+			"protected_settings": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateJsonString,
+				Sensitive:    true,
+			},
+		},
+	}
+}
+
+func resourceArmVirtualMachineExtensionsCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ArmClient)
+	vmExtensionClient := client.vmExtensionClient
+
+	name := d.Get("name").(string)
+	location := d.Get("location").(string)
+	resGroup := d.Get("resource_group_name").(string)
+	vmName := d.Get("virtual_machine_name").(string)
+	publisher := d.Get("publisher").(string)
+	extensionType := d.Get("type").(string)
+	typeHandlerVersion := d.Get("type_handler_version").(string)
+	autoUpgradeMinorVersion := d.Get("auto_upgrade_minor_version").(bool)
+
+	properties := compute.VirtualMachineExtensionProperties{
+		Publisher:               &publisher,
+		Type:                    &extensionType,
+		TypeHandlerVersion:      &typeHandlerVersion,
+		AutoUpgradeMinorVersion: &autoUpgradeMinorVersion,
+	}
+
+	if settings, ok := d.GetOk("settings"); ok {
+		settingsString := settings.(string)
+		settingsJSON, err := expandJsonFromString(settingsString)
+		if err != nil {
+			return fmt.Errorf("settings in not valid JSON: %s", err)
+		}
+		properties.Settings = &settingsJSON
+	}
+
+	if protectedSettings, ok := d.GetOk("protected_settings"); ok {
+		protectedSettingsString := protectedSettings.(string)
+		protectedSettingsJSON, err := expandJsonFromString(protectedSettingsString)
+		if err != nil {
+			return fmt.Errorf("protected_settings in not valid JSON: %s", err)
+		}
+		properties.ProtectedSettings = &protectedSettingsJSON
+	}
+
+	extension := compute.VirtualMachineExtension{
+		Name:       &name,
+		Location:   &location,
+		Properties: &properties,
+	}
+
+	_, err := vmExtensionClient.CreateOrUpdate(resGroup, vmName, name, extension, make(chan struct{}))
+	if err != nil {
+		return err
+	}
+
+	read, err := vmExtensionClient.Get(resGroup, vmName, name, "")
+	if err != nil {
+		return err
+	}
+	if read.ID == nil {
+		return fmt.Errorf("Cannot read Virtual Machine Extension %s (resource group %s) ID", name, resGroup)
+	}
+
+	d.SetId(*read.ID)
+
+	return resourceArmVirtualMachineExtensionsRead(d, meta)
+}
+
+func resourceArmVirtualMachineExtensionsRead(d *schema.ResourceData, meta interface{}) error {
+	vmExtensionClient := meta.(*ArmClient).vmExtensionClient
+
+	id, err := parseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+	resGroup := id.ResourceGroup
+	vmName := id.Path["virtualMachines"]
+	name := id.Path["extensions"]
+
+	resp, err := vmExtensionClient.Get(resGroup, vmName, name, "")
+	if resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Error making Read request on Azure Virtual Machine Extension %s: %s", name, err)
+	}
+
+	d.Set("name", resp.Name)
+	d.Set("resource_group_name", resGroup)
+	d.Set("virtual_machine_name", vmName)
+
+	if resp.Location != nil {
+		d.Set("location", azureRMNormalizeLocation(*resp.Location))
+	}
+
+	if resp.Properties == nil {
+		return nil
+	}
+
+	d.Set("publisher", resp.Properties.Publisher)
+	d.Set("type", resp.Properties.Type)
+	d.Set("type_handler_version", resp.Properties.TypeHandlerVersion)
+	d.Set("auto_upgrade_minor_version", resp.Properties.AutoUpgradeMinorVersion)
+
+	if resp.Properties.Settings != nil {
+		settings, err := flattenJsonToString(*resp.Properties.Settings)
+		if err != nil {
+			return fmt.Errorf("Error flattening settings for Virtual Machine Extension %s: %s", name, err)
+		}
+		d.Set("settings", settings)
+	}
+
+	if resp.Properties.ProtectedSettings != nil {
+		protectedSettings, err := flattenJsonToString(*resp.Properties.ProtectedSettings)
+		if err != nil {
+			return fmt.Errorf("Error flattening protected_settings for Virtual Machine Extension %s: %s", name, err)
+		}
+		d.Set("protected_settings", protectedSettings)
+	}
+
+	return nil
+}
+
+func resourceArmVirtualMachineExtensionsDelete(d *schema.ResourceData, meta interface{}) error {
+	vmExtensionClient := meta.(*ArmClient).vmExtensionClient
+
+	id, err := parseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+	resGroup := id.ResourceGroup
+	vmName := id.Path["virtualMachines"]
+	name := id.Path["extensions"]
+
+	_, err = vmExtensionClient.Delete(resGroup, vmName, name, make(chan struct{}))
+
+	return err
+}
+
+func validateJsonString(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := expandJsonFromString(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q contains an invalid JSON: %s", k, err))
+	}
+	return
+}
+
+func expandJsonFromString(jsonString string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+
+	err := json.Unmarshal([]byte(jsonString), &result)
+
+	return result, err
+}
+
+func flattenJsonToString(input map[string]interface{}) (string, error) {
+	result, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
@@ -0,0 +1,763 @@
+package azurerm
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceArmVirtualMachineScaleSet() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArmVirtualMachineScaleSetCreate,
+		Read:   resourceArmVirtualMachineScaleSetRead,
+		Update: resourceArmVirtualMachineScaleSetUpdate,
+		Delete: resourceArmVirtualMachineScaleSetDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"location": &schema.Schema{
+				Type:      schema.TypeString,
+				Required:  true,
+				ForceNew:  true,
+				StateFunc: azureRMNormalizeLocation,
+			},
+
+			"resource_group_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"sku": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"tier": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+
+						"capacity": &schema.Schema{
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+					},
+				},
+				Set: resourceArmVirtualMachineScaleSetSkuHash,
+			},
+
+			"upgrade_policy_mode": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"overprovision": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			"vm_size": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"storage_profile_image_reference": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"publisher": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"offer": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"sku": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"version": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+				Set: resourceArmVirtualMachineStorageImageReferenceHash,
+			},
+
+			"storage_profile_os_disk": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"image": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"vhd_containers": &schema.Schema{
+							Type:     schema.TypeSet,
+							Required: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+
+						"os_type": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"caching": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+
+						"create_option": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+				Set: resourceArmVirtualMachineScaleSetStorageProfileOsDiskHash,
+			},
+
+			"network_profile": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"primary": &schema.Schema{
+							Type:     schema.TypeBool,
+							Required: true,
+						},
+
+						"ip_configuration": &schema.Schema{
+							Type:     schema.TypeSet,
+							Required: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+
+									"subnet_id": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+
+									"load_balancer_backend_address_pool_ids": &schema.Schema{
+										Type:     schema.TypeSet,
+										Optional: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+										Set:      schema.HashString,
+									},
+								},
+							},
+						},
+					},
+				},
+				Set: resourceArmVirtualMachineScaleSetNetworkConfigurationHash,
+			},
+
+			"os_profile": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"computer_name_prefix": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+
+						"admin_username": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"admin_password": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"custom_data": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+				Set: resourceArmVirtualMachineScaleSetOsProfileHash,
+			},
+
+			"tags": tagsSchema(),
+		},
+	}
+}
+
+func resourceArmVirtualMachineScaleSetCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ArmClient)
+	vmScaleSetClient := client.vmScaleSetClient
+
+	log.Printf("[INFO] preparing arguments for Azure ARM Virtual Machine Scale Set creation.")
+
+	name := d.Get("name").(string)
+	location := d.Get("location").(string)
+	resGroup := d.Get("resource_group_name").(string)
+	tags := d.Get("tags").(map[string]interface{})
+
+	sku, err := expandAzureRMVirtualMachineScaleSetSku(d)
+	if err != nil {
+		return err
+	}
+
+	storageProfileOsDisk, err := expandAzureRMVirtualMachineScaleSetsStorageProfileOsDisk(d)
+	if err != nil {
+		return err
+	}
+
+	storageProfile := compute.VirtualMachineScaleSetStorageProfile{
+		OsDisk: storageProfileOsDisk,
+	}
+
+	if _, ok := d.GetOk("storage_profile_image_reference"); ok {
+		imageRef, err := expandAzureRmVirtualMachineImageReferenceFromField(d, "storage_profile_image_reference")
+		if err != nil {
+			return err
+		}
+		storageProfile.ImageReference = &imageRef
+	}
+
+	osProfile, err := expandAzureRMVirtualMachineScaleSetOsProfile(d)
+	if err != nil {
+		return err
+	}
+
+	networkProfile := expandAzureRMVirtualMachineScaleSetNetworkProfile(d)
+
+	upgradePolicyMode := d.Get("upgrade_policy_mode").(string)
+	overprovision := d.Get("overprovision").(bool)
+	vmSize := d.Get("vm_size").(string)
+
+	scaleSetProperties := compute.VirtualMachineScaleSetProperties{
+		UpgradePolicy: &compute.UpgradePolicy{
+			Mode: compute.UpgradeMode(upgradePolicyMode),
+		},
+		VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
+			StorageProfile: &storageProfile,
+			OsProfile:      &osProfile,
+			NetworkProfile: &networkProfile,
+			HardwareProfile: &compute.VirtualMachineScaleSetHardwareProfile{
+				VMSize: compute.VirtualMachineSizeTypes(vmSize),
+			},
+		},
+		Overprovision: &overprovision,
+	}
+
+	scaleSetParams := compute.VirtualMachineScaleSet{
+		Name:       &name,
+		Location:   &location,
+		Sku:        &sku,
+		Properties: &scaleSetProperties,
+		Tags:       expandTags(tags),
+	}
+
+	resp, err := vmScaleSetClient.CreateOrUpdate(resGroup, name, scaleSetParams, make(chan struct{}))
+	if err != nil {
+		return err
+	}
+
+	if resp.ID == nil {
+		return fmt.Errorf("Cannot read Virtual Machine Scale Set %s (resource group %s) ID", name, resGroup)
+	}
+
+	d.SetId(*resp.ID)
+
+	if err := resourceArmVirtualMachineScaleSetWaitForState(vmScaleSetClient, resGroup, name); err != nil {
+		return err
+	}
+
+	return resourceArmVirtualMachineScaleSetRead(d, meta)
+}
+
+func resourceArmVirtualMachineScaleSetRead(d *schema.ResourceData, meta interface{}) error {
+	vmScaleSetClient := meta.(*ArmClient).vmScaleSetClient
+
+	id, err := parseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+	resGroup := id.ResourceGroup
+	name := id.Path["virtualMachineScaleSets"]
+
+	resp, err := vmScaleSetClient.Get(resGroup, name)
+	if resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Error making Read request on Azure Virtual Machine Scale Set %s: %s", name, err)
+	}
+
+	d.Set("name", resp.Name)
+	d.Set("location", azureRMNormalizeLocation(*resp.Location))
+	d.Set("resource_group_name", resGroup)
+
+	if resp.Sku != nil {
+		if err := d.Set("sku", schema.NewSet(resourceArmVirtualMachineScaleSetSkuHash, flattenAzureRMVirtualMachineScaleSetSku(resp.Sku))); err != nil {
+			return fmt.Errorf("[ERROR] Error flattening sku: %s", err)
+		}
+	}
+
+	if resp.Properties != nil {
+		d.Set("overprovision", resp.Properties.Overprovision)
+
+		if resp.Properties.UpgradePolicy != nil {
+			d.Set("upgrade_policy_mode", string(resp.Properties.UpgradePolicy.Mode))
+		}
+
+		if profile := resp.Properties.VirtualMachineProfile; profile != nil {
+			if profile.HardwareProfile != nil {
+				d.Set("vm_size", string(profile.HardwareProfile.VMSize))
+			}
+
+			if storageProfile := profile.StorageProfile; storageProfile != nil {
+				if storageProfile.ImageReference != nil {
+					if err := d.Set("storage_profile_image_reference", schema.NewSet(resourceArmVirtualMachineStorageImageReferenceHash, flattenAzureRmVirtualMachineImageReference(storageProfile.ImageReference))); err != nil {
+						return fmt.Errorf("[ERROR] Error flattening storage_profile_image_reference: %s", err)
+					}
+				}
+
+				if storageProfile.OsDisk != nil {
+					if err := d.Set("storage_profile_os_disk", schema.NewSet(resourceArmVirtualMachineScaleSetStorageProfileOsDiskHash, flattenAzureRMVirtualMachineScaleSetStorageProfileOsDisk(storageProfile.OsDisk))); err != nil {
+						return fmt.Errorf("[ERROR] Error flattening storage_profile_os_disk: %s", err)
+					}
+				}
+			}
+
+			if profile.NetworkProfile != nil {
+				if err := d.Set("network_profile", schema.NewSet(resourceArmVirtualMachineScaleSetNetworkConfigurationHash, flattenAzureRMVirtualMachineScaleSetNetworkProfile(profile.NetworkProfile))); err != nil {
+					return fmt.Errorf("[ERROR] Error flattening network_profile: %s", err)
+				}
+			}
+
+			if profile.OsProfile != nil {
+				if err := d.Set("os_profile", schema.NewSet(resourceArmVirtualMachineScaleSetOsProfileHash, flattenAzureRMVirtualMachineScaleSetOsProfile(profile.OsProfile))); err != nil {
+					return fmt.Errorf("[ERROR] Error flattening os_profile: %s", err)
+				}
+			}
+		}
+	}
+
+	flattenAndSetTags(d, resp.Tags)
+
+	return nil
+}
+
+func resourceArmVirtualMachineScaleSetUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ArmClient)
+	vmScaleSetClient := client.vmScaleSetClient
+
+	resGroup := d.Get("resource_group_name").(string)
+	name := d.Get("name").(string)
+
+	if !d.HasChange("sku") && !d.HasChange("tags") {
+		return resourceArmVirtualMachineScaleSetRead(d, meta)
+	}
+
+	resp, err := vmScaleSetClient.Get(resGroup, name)
+	if err != nil {
+		return fmt.Errorf("Error reading Azure Virtual Machine Scale Set %s for update: %s", name, err)
+	}
+
+	if d.HasChange("sku") {
+		sku, err := expandAzureRMVirtualMachineScaleSetSku(d)
+		if err != nil {
+			return err
+		}
+		resp.Sku = &sku
+	}
+
+	if d.HasChange("tags") {
+		tags := d.Get("tags").(map[string]interface{})
+		resp.Tags = expandTags(tags)
+	}
+
+	_, err = vmScaleSetClient.CreateOrUpdate(resGroup, name, resp, make(chan struct{}))
+	if err != nil {
+		return fmt.Errorf("Error updating Azure Virtual Machine Scale Set %s: %s", name, err)
+	}
+
+	if err := resourceArmVirtualMachineScaleSetWaitForState(vmScaleSetClient, resGroup, name); err != nil {
+		return err
+	}
+
+	return resourceArmVirtualMachineScaleSetRead(d, meta)
+}
+
+func resourceArmVirtualMachineScaleSetDelete(d *schema.ResourceData, meta interface{}) error {
+	vmScaleSetClient := meta.(*ArmClient).vmScaleSetClient
+
+	id, err := parseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+	resGroup := id.ResourceGroup
+	name := id.Path["virtualMachineScaleSets"]
+
+	_, err = vmScaleSetClient.Delete(resGroup, name, make(chan struct{}))
+
+	return err
+}
+
+func resourceArmVirtualMachineScaleSetWaitForState(vmScaleSetClient compute.VirtualMachineScaleSetsClient, resGroup string, name string) error {
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"Updating", "Creating"},
+		Target:     []string{"Succeeded"},
+		Refresh:    resourceArmVirtualMachineScaleSetStateRefreshFunc(vmScaleSetClient, resGroup, name),
+		Timeout:    30 * time.Minute,
+		MinTimeout: 15 * time.Second,
+	}
+
+	_, err := stateConf.WaitForState()
+	return err
+}
+
+func resourceArmVirtualMachineScaleSetStateRefreshFunc(vmScaleSetClient compute.VirtualMachineScaleSetsClient, resGroup string, name string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		resp, err := vmScaleSetClient.Get(resGroup, name)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error issuing read request of Azure Virtual Machine Scale Set %s: %s", name, err)
+		}
+
+		if resp.Properties == nil || resp.Properties.ProvisioningState == nil {
+			return resp, "Creating", nil
+		}
+
+		state := *resp.Properties.ProvisioningState
+		if state == "Failed" {
+			return resp, state, fmt.Errorf("Azure Virtual Machine Scale Set %s entered a Failed provisioning state", name)
+		}
+
+		return resp, state, nil
+	}
+}
+
+func resourceArmVirtualMachineScaleSetSkuHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	buf.WriteString(fmt.Sprintf("%s-", m["name"].(string)))
+	buf.WriteString(fmt.Sprintf("%d-", m["capacity"].(int)))
+
+	return hashcode.String(buf.String())
+}
+
+func resourceArmVirtualMachineScaleSetStorageProfileOsDiskHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	buf.WriteString(fmt.Sprintf("%s-", m["name"].(string)))
+	buf.WriteString(fmt.Sprintf("%s-", m["create_option"].(string)))
+
+	return hashcode.String(buf.String())
+}
+
+func resourceArmVirtualMachineScaleSetNetworkConfigurationHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	buf.WriteString(fmt.Sprintf("%s-", m["name"].(string)))
+	buf.WriteString(fmt.Sprintf("%t-", m["primary"].(bool)))
+
+	return hashcode.String(buf.String())
+}
+
+func resourceArmVirtualMachineScaleSetOsProfileHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	buf.WriteString(fmt.Sprintf("%s-", m["computer_name_prefix"].(string)))
+	buf.WriteString(fmt.Sprintf("%s-", m["admin_username"].(string)))
+
+	return hashcode.String(buf.String())
+}
+
+func expandAzureRMVirtualMachineScaleSetSku(d *schema.ResourceData) (compute.Sku, error) {
+	skuConfig := d.Get("sku").(*schema.Set).List()
+
+	if len(skuConfig) != 1 {
+		return compute.Sku{}, fmt.Errorf("Cannot specify more than one sku.")
+	}
+
+	config := skuConfig[0].(map[string]interface{})
+
+	name := config["name"].(string)
+	tier := config["tier"].(string)
+	capacity := int64(config["capacity"].(int))
+
+	sku := compute.Sku{
+		Name:     &name,
+		Capacity: &capacity,
+	}
+
+	if tier != "" {
+		sku.Tier = &tier
+	}
+
+	return sku, nil
+}
+
+func expandAzureRMVirtualMachineScaleSetsStorageProfileOsDisk(d *schema.ResourceData) (*compute.VirtualMachineScaleSetOSDisk, error) {
+	osDiskConfig := d.Get("storage_profile_os_disk").(*schema.Set).List()
+
+	if len(osDiskConfig) != 1 {
+		return nil, fmt.Errorf("[ERROR] Only 1 storage_profile_os_disk can be specified for an Azure RM Virtual Machine Scale Set")
+	}
+
+	config := osDiskConfig[0].(map[string]interface{})
+
+	name := config["name"].(string)
+	createOption := config["create_option"].(string)
+
+	osDisk := &compute.VirtualMachineScaleSetOSDisk{
+		Name:         &name,
+		CreateOption: compute.DiskCreateOptionTypes(createOption),
+	}
+
+	if v := config["image"].(string); v != "" {
+		osDisk.Image = &compute.VirtualHardDisk{URI: &v}
+	}
+
+	if v := config["os_type"].(string); v != "" {
+		osDisk.OsType = compute.OperatingSystemTypes(v)
+	}
+
+	if v := config["caching"].(string); v != "" {
+		osDisk.Caching = compute.CachingTypes(v)
+	}
+
+	vhdContainers := config["vhd_containers"].(*schema.Set).List()
+	containers := make([]string, 0, len(vhdContainers))
+	for _, c := range vhdContainers {
+		containers = append(containers, c.(string))
+	}
+	osDisk.VhdContainers = &containers
+
+	return osDisk, nil
+}
+
+func expandAzureRMVirtualMachineScaleSetOsProfile(d *schema.ResourceData) (compute.VirtualMachineScaleSetOSProfile, error) {
+	osProfileConfig := d.Get("os_profile").(*schema.Set).List()
+
+	if len(osProfileConfig) != 1 {
+		return compute.VirtualMachineScaleSetOSProfile{}, fmt.Errorf("[ERROR] Only 1 os_profile can be specified for an Azure RM Virtual Machine Scale Set")
+	}
+
+	config := osProfileConfig[0].(map[string]interface{})
+
+	namePrefix := config["computer_name_prefix"].(string)
+	adminUsername := config["admin_username"].(string)
+	adminPassword := config["admin_password"].(string)
+
+	osProfile := compute.VirtualMachineScaleSetOSProfile{
+		ComputerNamePrefix: &namePrefix,
+		AdminUsername:      &adminUsername,
+		AdminPassword:      &adminPassword,
+	}
+
+	if v := config["custom_data"].(string); v != "" {
+		osProfile.CustomData = &v
+	}
+
+	return osProfile, nil
+}
+
+func expandAzureRMVirtualMachineScaleSetNetworkProfile(d *schema.ResourceData) compute.VirtualMachineScaleSetNetworkProfile {
+	networkConfigs := d.Get("network_profile").(*schema.Set).List()
+	networkProfileConfigs := make([]compute.VirtualMachineScaleSetNetworkConfiguration, 0, len(networkConfigs))
+
+	for _, nc := range networkConfigs {
+		config := nc.(map[string]interface{})
+
+		name := config["name"].(string)
+		primary := config["primary"].(bool)
+
+		ipConfigurationConfigs := config["ip_configuration"].(*schema.Set).List()
+		ipConfigurations := make([]compute.VirtualMachineScaleSetIPConfiguration, 0, len(ipConfigurationConfigs))
+		for _, ipc := range ipConfigurationConfigs {
+			ipConfig := ipc.(map[string]interface{})
+
+			ipName := ipConfig["name"].(string)
+			subnetID := ipConfig["subnet_id"].(string)
+
+			ipConfiguration := compute.VirtualMachineScaleSetIPConfiguration{
+				Name: &ipName,
+				Properties: &compute.VirtualMachineScaleSetIPConfigurationProperties{
+					Subnet: &compute.APIEntityReference{
+						ID: &subnetID,
+					},
+				},
+			}
+
+			pools := ipConfig["load_balancer_backend_address_pool_ids"].(*schema.Set).List()
+			if len(pools) > 0 {
+				addressPools := make([]compute.SubResource, 0, len(pools))
+				for _, p := range pools {
+					poolID := p.(string)
+					addressPools = append(addressPools, compute.SubResource{ID: &poolID})
+				}
+				ipConfiguration.Properties.LoadBalancerBackendAddressPools = &addressPools
+			}
+
+			ipConfigurations = append(ipConfigurations, ipConfiguration)
+		}
+
+		networkProfileConfigs = append(networkProfileConfigs, compute.VirtualMachineScaleSetNetworkConfiguration{
+			Name: &name,
+			Properties: &compute.VirtualMachineScaleSetNetworkConfigurationProperties{
+				Primary:          &primary,
+				IPConfigurations: &ipConfigurations,
+			},
+		})
+	}
+
+	return compute.VirtualMachineScaleSetNetworkProfile{
+		NetworkInterfaceConfigurations: &networkProfileConfigs,
+	}
+}
+
+func flattenAzureRMVirtualMachineScaleSetSku(sku *compute.Sku) []interface{} {
+	result := map[string]interface{}{
+		"name":     *sku.Name,
+		"capacity": int(*sku.Capacity),
+	}
+
+	if sku.Tier != nil {
+		result["tier"] = *sku.Tier
+	}
+
+	return []interface{}{result}
+}
+
+func flattenAzureRMVirtualMachineScaleSetStorageProfileOsDisk(disk *compute.VirtualMachineScaleSetOSDisk) []interface{} {
+	result := map[string]interface{}{
+		"name":          *disk.Name,
+		"create_option": string(disk.CreateOption),
+		"caching":       string(disk.Caching),
+		"os_type":       string(disk.OsType),
+	}
+
+	if disk.Image != nil {
+		result["image"] = *disk.Image.URI
+	}
+
+	if disk.VhdContainers != nil {
+		containers := make([]interface{}, 0, len(*disk.VhdContainers))
+		for _, c := range *disk.VhdContainers {
+			containers = append(containers, c)
+		}
+		result["vhd_containers"] = schema.NewSet(schema.HashString, containers)
+	}
+
+	return []interface{}{result}
+}
+
+func flattenAzureRMVirtualMachineScaleSetNetworkProfile(profile *compute.VirtualMachineScaleSetNetworkProfile) []interface{} {
+	result := make([]interface{}, 0)
+	if profile.NetworkInterfaceConfigurations == nil {
+		return result
+	}
+
+	for _, nic := range *profile.NetworkInterfaceConfigurations {
+		config := map[string]interface{}{
+			"name": *nic.Name,
+		}
+
+		if nic.Properties != nil {
+			if nic.Properties.Primary != nil {
+				config["primary"] = *nic.Properties.Primary
+			}
+
+			ipConfigs := make([]interface{}, 0)
+			if nic.Properties.IPConfigurations != nil {
+				for _, ipConfig := range *nic.Properties.IPConfigurations {
+					ipResult := map[string]interface{}{
+						"name": *ipConfig.Name,
+					}
+
+					if ipConfig.Properties != nil {
+						if ipConfig.Properties.Subnet != nil {
+							ipResult["subnet_id"] = *ipConfig.Properties.Subnet.ID
+						}
+
+						if ipConfig.Properties.LoadBalancerBackendAddressPools != nil {
+							pools := make([]interface{}, 0, len(*ipConfig.Properties.LoadBalancerBackendAddressPools))
+							for _, pool := range *ipConfig.Properties.LoadBalancerBackendAddressPools {
+								pools = append(pools, *pool.ID)
+							}
+							ipResult["load_balancer_backend_address_pool_ids"] = schema.NewSet(schema.HashString, pools)
+						}
+					}
+
+					ipConfigs = append(ipConfigs, ipResult)
+				}
+			}
+			config["ip_configuration"] = schema.NewSet(func(v interface{}) int {
+				m := v.(map[string]interface{})
+				return hashcode.String(m["name"].(string))
+			}, ipConfigs)
+		}
+
+		result = append(result, config)
+	}
+
+	return result
+}
+
+func flattenAzureRMVirtualMachineScaleSetOsProfile(profile *compute.VirtualMachineScaleSetOSProfile) []interface{} {
+	result := map[string]interface{}{
+		"computer_name_prefix": *profile.ComputerNamePrefix,
+		"admin_username":       *profile.AdminUsername,
+	}
+
+	if profile.CustomData != nil {
+		result["custom_data"] = *profile.CustomData
+	}
+
+	return []interface{}{result}
+}